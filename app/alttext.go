@@ -0,0 +1,95 @@
+package app
+
+import (
+	"html"
+	"mime"
+	"path"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// cdataStart and cdataEnd bracket a CDATA section that xml.Decoder
+// leaves untouched in ",innerxml" captures. net/html doesn't understand
+// CDATA outside foreign (SVG/MathML) content and treats "<![CDATA[" as a
+// bogus comment, so the markers are stripped before parsing; the
+// markup they wrap is left exactly as written.
+const (
+	cdataStart = "<![CDATA["
+	cdataEnd   = "]]>"
+)
+
+func stripCDATAMarkers(s string) string {
+	s = strings.ReplaceAll(s, cdataStart, "")
+	return strings.ReplaceAll(s, cdataEnd, "")
+}
+
+// firstImg parses e.Summary.Body as an HTML fragment and returns the
+// first <img> element it contains, or nil if there is none. Using a real
+// parser (rather than a regexp over the raw inner XML) copes with
+// single-quoted attributes, escaped entities, CDATA-wrapped markup, and
+// compound comics that embed more than one <img> tag. Atom summaries of
+// type "html" carry their markup HTML-escaped, so the body must be
+// unescaped before parsing it as HTML.
+func (e *Entry) firstImg() *nethtml.Node {
+	body := stripCDATAMarkers(e.Summary.Body)
+	doc, err := nethtml.Parse(strings.NewReader(html.UnescapeString(body)))
+	if err != nil {
+		return nil
+	}
+	var img *nethtml.Node
+	var walk func(*nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		if img != nil {
+			return
+		}
+		if n.Type == nethtml.ElementNode && n.Data == "img" {
+			img = n
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return img
+}
+
+func imgAttr(img *nethtml.Node, name string) string {
+	if img == nil {
+		return ""
+	}
+	for _, attr := range img.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// AltText returns the alt text of the first image in the entry's
+// summary, or "" if there is none.
+func (e *Entry) AltText() string {
+	return imgAttr(e.firstImg(), "alt")
+}
+
+// ImageURL returns the src of the first image in the entry's summary, or
+// "" if there is none.
+func (e *Entry) ImageURL() string {
+	return imgAttr(e.firstImg(), "src")
+}
+
+// ImageSrcset returns the srcset of the first image in the entry's
+// summary, or "" if the image has none.
+func (e *Entry) ImageSrcset() string {
+	return imgAttr(e.firstImg(), "srcset")
+}
+
+// imageMimeType guesses the MIME type of an image URL from its file
+// extension, defaulting to "application/octet-stream" when unknown.
+func imageMimeType(imgURL string) string {
+	if t := mime.TypeByExtension(path.Ext(imgURL)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,80 @@
+package app
+
+import "testing"
+
+func TestAltTextPathologicalSummaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantAlt string
+		wantSrc string
+	}{
+		{
+			name:    "double quoted",
+			body:    `<img src="https://imgs.xkcd.com/comics/1.png" alt="hello world">`,
+			wantAlt: "hello world",
+			wantSrc: "https://imgs.xkcd.com/comics/1.png",
+		},
+		{
+			name:    "single quoted",
+			body:    `<img src='https://imgs.xkcd.com/comics/1.png' alt='hello world'>`,
+			wantAlt: "hello world",
+			wantSrc: "https://imgs.xkcd.com/comics/1.png",
+		},
+		{
+			// Atom summaries of type "html" are XML-escaped once for
+			// transport, so an ampersand in the original alt text shows
+			// up double-escaped in the captured innerxml.
+			name:    "double-escaped ampersand",
+			body:    `<img src="https://imgs.xkcd.com/comics/1.png" alt="Rock &amp;amp; Roll">`,
+			wantAlt: "Rock & Roll",
+			wantSrc: "https://imgs.xkcd.com/comics/1.png",
+		},
+		{
+			name:    "compound comic with multiple images",
+			body:    `<img src="https://imgs.xkcd.com/comics/1a.png" alt="first panel"><img src="https://imgs.xkcd.com/comics/1b.png" alt="second panel">`,
+			wantAlt: "first panel",
+			wantSrc: "https://imgs.xkcd.com/comics/1a.png",
+		},
+		{
+			name:    "CDATA wrapped",
+			body:    `<![CDATA[<img src="https://imgs.xkcd.com/comics/1.png" alt="hello world">]]>`,
+			wantAlt: "hello world",
+			wantSrc: "https://imgs.xkcd.com/comics/1.png",
+		},
+		{
+			name:    "no image",
+			body:    `just some text, no image here`,
+			wantAlt: "",
+			wantSrc: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{}
+			e.Summary.Body = tt.body
+			if got := e.AltText(); got != tt.wantAlt {
+				t.Errorf("AltText() = %q, want %q", got, tt.wantAlt)
+			}
+			if got := e.ImageURL(); got != tt.wantSrc {
+				t.Errorf("ImageURL() = %q, want %q", got, tt.wantSrc)
+			}
+		})
+	}
+}
+
+func TestImageMimeType(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://imgs.xkcd.com/comics/1.png", "image/png"},
+		{"https://imgs.xkcd.com/comics/1.jpg", "image/jpeg"},
+		{"https://imgs.xkcd.com/comics/1", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		if got := imageMimeType(tt.url); got != tt.want {
+			t.Errorf("imageMimeType(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
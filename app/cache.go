@@ -0,0 +1,157 @@
+package app
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+
+	"appengine"
+	"appengine/memcache"
+	"appengine/urlfetch"
+)
+
+var (
+	httpExp          = regexp.MustCompile(`http://(imgs\.)?xkcd.com`)
+	httpsReplacement = []byte("https://${1}xkcd.com")
+)
+
+const (
+	atomKey = "/xkcd.atom"
+
+	// atomValidatorsKey holds the same feedCacheEntry as atomKey but with
+	// a much longer expiration, so the upstream ETag/Last-Modified
+	// survive past atomKey's short TTL and are still available to send
+	// on the next conditional GET even when the fast-path body cache has
+	// expired.
+	atomValidatorsKey = "/xkcd.atom.validators"
+
+	atomTTL           = 5 * time.Minute
+	atomValidatorsTTL = 7 * 24 * time.Hour
+)
+
+// feedCacheEntry is what gets stored in memcache under atomKey: the
+// parsed feed plus the upstream validators needed to make a conditional
+// request next time, so a 304 from xkcd only costs us refreshing the
+// expiration timestamp instead of a full re-fetch and re-parse.
+type feedCacheEntry struct {
+	XMLName      xml.Name `xml:"cacheEntry"`
+	ETag         string   `xml:"etag,omitempty"`
+	LastModified string   `xml:"lastModified,omitempty"`
+	FeedXML      []byte   `xml:"feedXML"`
+}
+
+// getUpstreamAtom fetches the upstream xkcd feed, sending a conditional
+// request when prevETag/prevLastModified are non-empty. notModified is
+// true (and feed is nil) when upstream replied 304.
+func getUpstreamAtom(ctx appengine.Context, prevETag, prevLastModified string) (feed *Feed, etag, lastModified string, notModified bool, err error) {
+	client := urlfetch.Client(ctx)
+	req, err := http.NewRequest("GET", "https://xkcd.com/atom.xml", nil)
+	if err != nil {
+		return nil, "", "", false, err
+	}
+	if prevETag != "" {
+		req.Header.Set("If-None-Match", prevETag)
+	}
+	if prevLastModified != "" {
+		req.Header.Set("If-Modified-Since", prevLastModified)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, prevETag, prevLastModified, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", "", false, fmt.Errorf("http request was not OK")
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", "", false, fmt.Errorf("failed to read response: %v", err)
+	}
+	b = httpExp.ReplaceAll(b, httpsReplacement)
+	feed, err = parseFeedBytes(b)
+	if err != nil {
+		return nil, "", "", false, err
+	}
+	return feed, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), false, nil
+}
+
+// cachingGetUpstreamAtom returns the cached feed along with the ETag and
+// Last-Modified that should be advertised on our own responses,
+// refreshing the cache from upstream (with a conditional GET, if we have
+// validators to send) on expiration.
+func cachingGetUpstreamAtom(ctx appengine.Context) (feed *Feed, etag, lastModified string, err error) {
+	if item, cacheErr := memcache.Get(ctx, atomKey); cacheErr == nil {
+		var cur feedCacheEntry
+		if err := xml.Unmarshal(item.Value, &cur); err == nil {
+			feed, err := unmarshalCachedFeed(&cur)
+			if err == nil {
+				ctx.Infof("found feed in cache")
+				return feed, cur.ETag, cur.LastModified, nil
+			}
+		}
+	}
+
+	// atomKey has expired (or was never set), but the upstream
+	// validators may still be good for a while longer: fetch them from
+	// the long-lived entry so the request to xkcd.com can still be
+	// conditional.
+	var prev feedCacheEntry
+	if item, cacheErr := memcache.Get(ctx, atomValidatorsKey); cacheErr == nil {
+		xml.Unmarshal(item.Value, &prev)
+	}
+
+	ctx.Infof("making request to xkcd.com")
+	fetched, newETag, newLastModified, notModified, err := getUpstreamAtom(ctx, prev.ETag, prev.LastModified)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if notModified {
+		feed, err := unmarshalCachedFeed(&prev)
+		if err != nil {
+			return nil, "", "", err
+		}
+		setCache(ctx, &prev)
+		return feed, prev.ETag, prev.LastModified, nil
+	}
+
+	feedXML, err := xml.Marshal(fetched)
+	if err == nil {
+		entry := &feedCacheEntry{ETag: newETag, LastModified: newLastModified, FeedXML: feedXML}
+		setCache(ctx, entry)
+	}
+	return fetched, newETag, newLastModified, nil
+}
+
+func unmarshalCachedFeed(entry *feedCacheEntry) (*Feed, error) {
+	var feed Feed
+	if err := xml.Unmarshal(entry.FeedXML, &feed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached feed: %v", err)
+	}
+	return &feed, nil
+}
+
+// setCache stores entry under both the short-TTL fast-path key and the
+// long-TTL validators key, so the ETag/Last-Modified it carries remain
+// available for a conditional GET long after the fast-path entry expires.
+func setCache(ctx appengine.Context, entry *feedCacheEntry) {
+	b, err := xml.Marshal(entry)
+	if err != nil {
+		return
+	}
+	memcache.Set(ctx, &memcache.Item{
+		Key:        atomKey,
+		Value:      b,
+		Expiration: atomTTL,
+	})
+	memcache.Set(ctx, &memcache.Item{
+		Key:        atomValidatorsKey,
+		Value:      b,
+		Expiration: atomValidatorsTTL,
+	})
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type Link struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type Feed struct {
+	XMLName  xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang     string   `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Title    string   `xml:"title"`
+	Subtitle string   `xml:"subtitle,omitempty"`
+	Rights   string   `xml:"rights,omitempty"`
+	Link     []Link   `xml:"link"`
+	ID       string   `xml:"id"`
+	Updated  string   `xml:"updated"`
+	Entry    []Entry  `xml:"entry"`
+}
+
+type Entry struct {
+	Title     string `xml:"title"`
+	Link      []Link `xml:"link"`
+	Updated   string `xml:"updated"`
+	Published string `xml:"published,omitempty"`
+	ID        string `xml:"id"`
+	Summary   struct {
+		Type string `xml:"type,attr,omitempty"`
+		Body string `xml:",innerxml"`
+	} `xml:"summary"`
+}
+
+// atom03Feed and atom03Entry mirror the Atom 0.3 schema
+// (http://purl.org/atom/ns#), which predates the "updated"/"published"
+// and "subtitle"/"rights" naming used by Atom 1.0.
+type atom03Feed struct {
+	XMLName   xml.Name      `xml:"http://purl.org/atom/ns# feed"`
+	Title     string        `xml:"title"`
+	Tagline   string        `xml:"tagline"`
+	Copyright string        `xml:"copyright"`
+	Link      []Link        `xml:"link"`
+	ID        string        `xml:"id"`
+	Modified  string        `xml:"modified"`
+	Entry     []atom03Entry `xml:"entry"`
+}
+
+type atom03Entry struct {
+	Title    string `xml:"title"`
+	Link     []Link `xml:"link"`
+	Issued   string `xml:"issued"`
+	Modified string `xml:"modified"`
+	ID       string `xml:"id"`
+	Summary  struct {
+		Type string `xml:"type,attr,omitempty"`
+		Body string `xml:",innerxml"`
+	} `xml:"summary"`
+}
+
+const (
+	atomNS10 = "http://www.w3.org/2005/Atom"
+	atomNS03 = "http://purl.org/atom/ns#"
+)
+
+// detectFeedRoot peeks at the first element of b without consuming the
+// whole document, returning its namespace and local name so the caller
+// can dispatch to the right unmarshal target in a single pass.
+func detectFeedRoot(b []byte) (ns, local string, err error) {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to find root element: %v", err)
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		ns = se.Name.Space
+		local = se.Name.Local
+		if ns == "" && local == "feed" {
+			// Atom 0.3 documents sometimes omit the default xmlns on the
+			// decoder's view of Name.Space depending on how they were
+			// produced; fall back to the version attribute.
+			for _, attr := range se.Attr {
+				if attr.Name.Local == "version" && attr.Value == "0.3" {
+					ns = atomNS03
+				}
+			}
+		}
+		return ns, local, nil
+	}
+}
+
+// parseFeedBytes unmarshals an upstream feed document, auto-detecting
+// whether it is Atom 1.0, Atom 0.3, or RSS 2.0, and normalizes the result
+// into the canonical Feed type so downstream code never has to care which
+// format the bytes were in.
+func parseFeedBytes(b []byte) (*Feed, error) {
+	ns, local, err := detectFeedRoot(b)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case local == "feed" && ns == atomNS03:
+		var f03 atom03Feed
+		if err := xml.Unmarshal(b, &f03); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal atom 0.3 feed: %v", err)
+		}
+		return atom03ToFeed(&f03), nil
+	case local == "feed" && ns == atomNS10:
+		var feed Feed
+		if err := xml.Unmarshal(b, &feed); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal atom feed: %v", err)
+		}
+		return &feed, nil
+	case local == "rss":
+		return nil, fmt.Errorf("upstream feed is RSS 2.0, not Atom; parsing RSS input is not supported")
+	default:
+		return nil, fmt.Errorf("unrecognized feed root element %q", local)
+	}
+}
+
+func atom03ToFeed(f *atom03Feed) *Feed {
+	feed := &Feed{
+		Title:    f.Title,
+		Subtitle: f.Tagline,
+		Rights:   f.Copyright,
+		Link:     f.Link,
+		ID:       f.ID,
+		Updated:  f.Modified,
+	}
+	for _, e := range f.Entry {
+		entry := Entry{
+			Title:     e.Title,
+			Link:      e.Link,
+			Updated:   e.Modified,
+			Published: e.Issued,
+			ID:        e.ID,
+		}
+		entry.Summary.Type = e.Summary.Type
+		entry.Summary.Body = e.Summary.Body
+		feed.Entry = append(feed.Entry, entry)
+	}
+	return feed
+}
+
+// encodeAtom marshals feed as an Atom 1.0 document, optionally preceding
+// it with an xml-stylesheet processing instruction so browsers render it
+// nicely instead of showing raw XML.
+func encodeAtom(feed *Feed, stylesheet string) ([]byte, error) {
+	b, err := xml.Marshal(feed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feed: %v", err)
+	}
+	var buf bytes.Buffer
+	io.WriteString(&buf, xml.Header)
+	if stylesheet != "" {
+		fmt.Fprintf(&buf, "<?xml-stylesheet href=%q type=\"text/xsl\"?>\n", stylesheet)
+	}
+	buf.Write(b)
+	return buf.Bytes(), nil
+}
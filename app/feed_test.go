@@ -0,0 +1,127 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const atom10Sample = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>xkcd.com</title>
+<link href="https://xkcd.com/" rel="alternate"/>
+<id>https://xkcd.com/</id>
+<updated>2026-07-28T00:00:00Z</updated>
+<entry>
+<title>Example</title>
+<link href="https://xkcd.com/1/" rel="alternate"/>
+<updated>2026-07-28T00:00:00Z</updated>
+<id>https://xkcd.com/1/</id>
+<summary type="html">&lt;img src="https://imgs.xkcd.com/comics/1.png" alt="an alt text"&gt;</summary>
+</entry>
+</feed>
+`
+
+const atom03Sample = `<?xml version="1.0" encoding="utf-8"?>
+<feed version="0.3" xmlns="http://purl.org/atom/ns#">
+<title>xkcd.com</title>
+<tagline>A webcomic</tagline>
+<copyright>xkcd.com</copyright>
+<link href="https://xkcd.com/" rel="alternate"/>
+<id>https://xkcd.com/</id>
+<modified>2026-07-28T00:00:00Z</modified>
+<entry>
+<title>Example</title>
+<link href="https://xkcd.com/1/" rel="alternate"/>
+<issued>2026-07-27T00:00:00Z</issued>
+<modified>2026-07-28T00:00:00Z</modified>
+<id>https://xkcd.com/1/</id>
+<summary type="html">&lt;img src="https://imgs.xkcd.com/comics/1.png" alt="an alt text"&gt;</summary>
+</entry>
+</feed>
+`
+
+const rss20Sample = `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0">
+<channel>
+<title>xkcd.com</title>
+<item><title>Example</title></item>
+</channel>
+</rss>
+`
+
+func TestParseFeedBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantErr   bool
+		wantTitle string
+		wantAlt   string
+	}{
+		{name: "atom 1.0", input: atom10Sample, wantTitle: "xkcd.com", wantAlt: "an alt text"},
+		{name: "atom 0.3", input: atom03Sample, wantTitle: "xkcd.com", wantAlt: "an alt text"},
+		{name: "rss 2.0 fallback", input: rss20Sample, wantErr: true},
+		{name: "garbage", input: "not xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := parseFeedBytes([]byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseFeedBytes(%s) = nil error, want error", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFeedBytes(%s) = %v, want no error", tt.name, err)
+			}
+			if feed.Title != tt.wantTitle {
+				t.Errorf("feed.Title = %q, want %q", feed.Title, tt.wantTitle)
+			}
+			if len(feed.Entry) != 1 {
+				t.Fatalf("len(feed.Entry) = %d, want 1", len(feed.Entry))
+			}
+			if got := feed.Entry[0].AltText(); got != tt.wantAlt {
+				t.Errorf("feed.Entry[0].AltText() = %q, want %q", got, tt.wantAlt)
+			}
+		})
+	}
+}
+
+func TestAtom03ToFeedCarriesMetadata(t *testing.T) {
+	feed, err := parseFeedBytes([]byte(atom03Sample))
+	if err != nil {
+		t.Fatalf("parseFeedBytes: %v", err)
+	}
+	if feed.Subtitle != "A webcomic" {
+		t.Errorf("feed.Subtitle = %q, want %q", feed.Subtitle, "A webcomic")
+	}
+	if feed.Rights != "xkcd.com" {
+		t.Errorf("feed.Rights = %q, want %q", feed.Rights, "xkcd.com")
+	}
+	if feed.Entry[0].Published != "2026-07-27T00:00:00Z" {
+		t.Errorf("feed.Entry[0].Published = %q, want %q", feed.Entry[0].Published, "2026-07-27T00:00:00Z")
+	}
+}
+
+func TestEncodeAtomStylesheetPrecedesFeed(t *testing.T) {
+	feed, err := parseFeedBytes([]byte(atom10Sample))
+	if err != nil {
+		t.Fatalf("parseFeedBytes: %v", err)
+	}
+	b, err := encodeAtom(feed, "/static/feed.xsl")
+	if err != nil {
+		t.Fatalf("encodeAtom: %v", err)
+	}
+	piIdx := bytes.Index(b, []byte("<?xml-stylesheet"))
+	feedIdx := bytes.Index(b, []byte("<feed"))
+	if piIdx < 0 {
+		t.Fatalf("output missing xml-stylesheet PI: %s", b)
+	}
+	if feedIdx < 0 || piIdx > feedIdx {
+		t.Fatalf("xml-stylesheet PI did not precede <feed> element: %s", b)
+	}
+	if !strings.Contains(string(b), `href="/static/feed.xsl"`) {
+		t.Errorf("output missing stylesheet href: %s", b)
+	}
+}
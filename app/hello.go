@@ -1,137 +1,71 @@
 package app
 
 import (
-	"encoding/xml"
-	"errors"
-	"fmt"
 	"html"
 	"html/template"
-	"io"
-	"io/ioutil"
 	"net/http"
-	"regexp"
-	"time"
 
 	"appengine"
-	"appengine/memcache"
-	"appengine/urlfetch"
 )
 
-type Link struct {
-	Href string `xml:"href,attr"`
-	Rel  string `xml:"rel,attr,omitempty"`
-}
-
-type Feed struct {
-	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
-	Lang    string   `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
-	Title   string   `xml:"title"`
-	Link    []Link   `xml:"link"`
-	ID      string   `xml:"id"`
-	Updated string   `xml:"updated"`
-	Entry   []Entry  `xml:"entry"`
-}
-
-type Entry struct {
-	Title   string `xml:"title"`
-	Link    []Link `xml:"link"`
-	Updated string `xml:"updated"`
-	ID      string `xml:"id"`
-	Summary struct {
-		Type string `xml:"type,attr,omitempty"`
-		Body string `xml:",innerxml"`
-	} `xml:"summary"`
-}
-
-var (
-	altExp           = regexp.MustCompile(`alt="[^"]*"`)
-	httpExp          = regexp.MustCompile(`http://(imgs\.)?xkcd.com`)
-	httpsReplacement = []byte("https://${1}xkcd.com")
-)
-
-func (e *Entry) AltText() string {
-	s := altExp.FindString(e.Summary.Body)
-	if s == "" {
-		return s
-	}
-	return s[5 : len(s)-1]
-}
-
 func init() {
 	http.HandleFunc("/atom.xml", atomHandler)
+	http.HandleFunc("/rss.xml", rssHandler)
+	http.HandleFunc("/feed.json", jsonFeedHandler)
+	http.HandleFunc("/static/feed.xsl", staticFeedXSLHandler)
 	http.HandleFunc("/", mainHandler)
 }
 
-func getUpstreamAtom(ctx appengine.Context) (*Feed, error) {
-	client := urlfetch.Client(ctx)
-	resp, err := client.Get("https://xkcd.com/atom.xml")
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("http request was not OK")
-	}
-	defer resp.Body.Close()
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
-	b = httpExp.ReplaceAll(b, httpsReplacement)
-	var feed Feed
-	if err := xml.Unmarshal(b, &feed); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal feed: %v", err)
-	}
-	return &feed, nil
+// requestURL reconstructs the absolute URL the client used to reach r,
+// for formats (like JSON Feed) that need to advertise their own address.
+// App Engine terminates TLS upstream of the app, so r.TLS is never set
+// even for HTTPS requests; this app is only ever served over HTTPS.
+func requestURL(r *http.Request) string {
+	return "https://" + r.Host + r.URL.Path
 }
 
-const atomKey = "/xkcd.atom"
-
-func cachingGetUpstreamAtom(ctx appengine.Context) (*Feed, error) {
-	item, err := memcache.Get(ctx, atomKey)
-	if err != nil {
-		ctx.Infof("making request to xkcd.com")
-		feed, err := getUpstreamAtom(ctx)
-		if err != nil {
-			return nil, err
-		}
-		if b, err := xml.Marshal(feed); err == nil {
-			item = &memcache.Item{
-				Key:        atomKey,
-				Value:      b,
-				Expiration: 5 * time.Minute,
-			}
-			memcache.Set(ctx, item)
-		}
-		return feed, nil
-	}
-	ctx.Infof("found feed in cache")
-	var feed Feed
-	if err := xml.Unmarshal(item.Value, &feed); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cached feed: %v", err)
-	}
-	return &feed, nil
-}
-
-func atomHandler(w http.ResponseWriter, r *http.Request) {
+// serveFeed fetches the cached upstream feed, renders it in the given
+// format, and writes it to w, honoring If-None-Match against the
+// upstream ETag so polling aggregators that already speak conditional
+// GET (e.g. Miniflux) don't re-download the body on every poll.
+func serveFeed(w http.ResponseWriter, r *http.Request, format string) {
 	ctx := appengine.NewContext(r)
-	feed, err := cachingGetUpstreamAtom(ctx)
+	feed, etag, lastModified, err := cachingGetUpstreamAtom(ctx)
 	if err != nil {
 		http.Error(w, "failed to get upstream atom: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	for i := range feed.Entry {
-		feed.Entry[i].Summary.Body += "\n" + feed.Entry[i].AltText()
+	if etag != "" {
+		w.Header().Set("ETag", etag)
 	}
-	b, err := xml.Marshal(feed)
+	if lastModified != "" {
+		w.Header().Set("Last-Modified", lastModified)
+	}
+	if etag != "" && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	b, contentType, err := renderFeed(format, feed, requestURL(r))
 	if err != nil {
-		http.Error(w, "failed to marshal feed", http.StatusInternalServerError)
+		http.Error(w, "failed to render feed: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/atom+xml")
-	io.WriteString(w, xml.Header)
+	w.Header().Set("Content-Type", contentType)
 	w.Write(b)
 }
 
+func atomHandler(w http.ResponseWriter, r *http.Request) {
+	serveFeed(w, r, "atom")
+}
+
+func rssHandler(w http.ResponseWriter, r *http.Request) {
+	serveFeed(w, r, "rss")
+}
+
+func jsonFeedHandler(w http.ResponseWriter, r *http.Request) {
+	serveFeed(w, r, "json")
+}
+
 const mainRawTemplate = `<!doctype html>
 <html>
 <title>xkcd with subs</title>
@@ -168,7 +102,7 @@ type pageEntry struct {
 
 func mainHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
-	feed, err := cachingGetUpstreamAtom(ctx)
+	feed, _, _, err := cachingGetUpstreamAtom(ctx)
 	if err != nil {
 		http.Error(w, "failed to get upstream atom: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -176,7 +110,7 @@ func mainHandler(w http.ResponseWriter, r *http.Request) {
 	var entries []pageEntry
 	for i := range feed.Entry {
 		imgTag := html.UnescapeString(feed.Entry[i].Summary.Body)
-		text := html.UnescapeString(feed.Entry[i].AltText())
+		text := feed.Entry[i].AltText()
 		entries = append(entries, pageEntry{
 			Title: feed.Entry[i].Title,
 			Img:   template.HTML(imgTag),
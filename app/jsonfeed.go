@@ -0,0 +1,79 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type jsonFeedDoc struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	ContentHTML   string               `json:"content_html"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+}
+
+// renderJSONFeed converts feed into a JSON Feed 1.1 document
+// (https://www.jsonfeed.org/) for clients like NetNewsWire and Feedbin
+// that speak JSON Feed natively. feedURL is this document's own absolute
+// URL, which the spec requires feed_url to be.
+func renderJSONFeed(feed *Feed, feedURL string) ([]byte, error) {
+	doc := jsonFeedDoc{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feedLink(feed),
+		FeedURL:     feedURL,
+	}
+	for _, e := range toCanonicalEntries(feed) {
+		item := jsonFeedItem{
+			ID:            e.ID,
+			URL:           e.Link,
+			Title:         e.Title,
+			DatePublished: jsonFeedDate(e),
+			ContentHTML:   fmt.Sprintf(`<img src="%s">%s`, e.ImageURL, e.AltText),
+		}
+		if e.ImageURL != "" {
+			item.Attachments = []jsonFeedAttachment{{
+				URL:      e.ImageURL,
+				MimeType: imageMimeType(e.ImageURL),
+			}}
+		}
+		doc.Items = append(doc.Items, item)
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal json feed: %v", err)
+	}
+	return b, nil
+}
+
+// jsonFeedDate formats an entry's timestamp (Published, else Updated) as
+// RFC3339, returning "" if there is none or it doesn't parse as RFC3339.
+func jsonFeedDate(e canonicalEntry) string {
+	ts := e.Published
+	if ts == "" {
+		ts = e.Updated
+	}
+	if ts == "" {
+		return ""
+	}
+	if t, err := time.Parse(time.RFC3339, ts); err == nil {
+		return t.Format(time.RFC3339)
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// canonicalEntry is a flattened, format-agnostic view of an Entry used by
+// the RSS and JSON Feed renderers so they don't each have to re-derive
+// the image URL and alt text from the raw Atom summary.
+type canonicalEntry struct {
+	Title     string
+	Link      string
+	ID        string
+	Updated   string
+	Published string
+	ImageURL  string
+	AltText   string
+}
+
+// alternateLink returns the href of the first link in links whose rel is
+// "alternate" or absent (Atom's default), or of the first link if none
+// is, shared by feed- and entry-level link resolution.
+func alternateLink(links []Link) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// toCanonicalEntries flattens feed.Entry into canonicalEntry values,
+// shared by renderRSS and renderJSONFeed.
+func toCanonicalEntries(feed *Feed) []canonicalEntry {
+	entries := make([]canonicalEntry, 0, len(feed.Entry))
+	for i := range feed.Entry {
+		e := &feed.Entry[i]
+		img := e.firstImg()
+		entries = append(entries, canonicalEntry{
+			Title:     e.Title,
+			Link:      alternateLink(e.Link),
+			ID:        e.ID,
+			Updated:   e.Updated,
+			Published: e.Published,
+			ImageURL:  imgAttr(img, "src"),
+			AltText:   imgAttr(img, "alt"),
+		})
+	}
+	return entries
+}
+
+// renderFeed marshals feed in the requested output format, returning the
+// body and the Content-Type that should be advertised for it. feedURL is
+// the absolute URL this output is being served from (e.g.
+// "https://host/feed.json"), used by formats that advertise their own
+// address. It is the single code path shared by the /atom.xml, /rss.xml,
+// and /feed.json handlers.
+func renderFeed(format string, feed *Feed, feedURL string) ([]byte, string, error) {
+	switch format {
+	case "atom":
+		for i := range feed.Entry {
+			var escaped bytes.Buffer
+			if err := xml.EscapeText(&escaped, []byte(feed.Entry[i].AltText())); err != nil {
+				return nil, "", fmt.Errorf("failed to escape alt text: %v", err)
+			}
+			feed.Entry[i].Summary.Body += "\n" + escaped.String()
+		}
+		b, err := encodeAtom(feed, "/static/feed.xsl")
+		return b, "application/atom+xml", err
+	case "rss":
+		b, err := renderRSS(feed)
+		return b, "application/rss+xml", err
+	case "json":
+		b, err := renderJSONFeed(feed, feedURL)
+		return b, "application/feed+json", err
+	default:
+		return nil, "", fmt.Errorf("unknown feed format %q", format)
+	}
+}
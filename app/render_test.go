@@ -0,0 +1,36 @@
+package app
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestRenderFeedAtomEscapesAltText(t *testing.T) {
+	feed := &Feed{
+		Title:   "xkcd.com",
+		ID:      "https://xkcd.com/",
+		Updated: "2026-07-28T00:00:00Z",
+		Entry: []Entry{{
+			Title:   "Example",
+			ID:      "https://xkcd.com/1/",
+			Updated: "2026-07-28T00:00:00Z",
+		}},
+	}
+	feed.Entry[0].Summary.Type = "html"
+	// Atom summaries of type "html" carry their markup HTML-escaped, so
+	// a literal "<img>" never reaches Summary.Body unescaped; this is
+	// the shape xml.Unmarshal actually produces (compare atom10Sample in
+	// feed_test.go), with a double-escaped ampersand in the alt text.
+	feed.Entry[0].Summary.Body = `&lt;img src="https://imgs.xkcd.com/comics/1.png" alt="Rock &amp;amp; Roll"&gt;`
+
+	b, contentType, err := renderFeed("atom", feed, "https://xkcdfeed.example/atom.xml")
+	if err != nil {
+		t.Fatalf("renderFeed: %v", err)
+	}
+	if contentType != "application/atom+xml" {
+		t.Errorf("contentType = %q, want application/atom+xml", contentType)
+	}
+	if err := xml.Unmarshal(b, new(Feed)); err != nil {
+		t.Fatalf("renderFeed produced non-well-formed XML: %v\n%s", err, b)
+	}
+}
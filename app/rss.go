@@ -0,0 +1,76 @@
+package app
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Item        []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description"`
+}
+
+// renderRSS converts feed into an RSS 2.0 document for clients that
+// don't speak Atom, such as podcast apps and legacy webhooks.
+func renderRSS(feed *Feed) ([]byte, error) {
+	channel := rssChannel{
+		Title:       feed.Title,
+		Link:        feedLink(feed),
+		Description: feed.Subtitle,
+	}
+	for _, e := range toCanonicalEntries(feed) {
+		channel.Item = append(channel.Item, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        e.ID,
+			PubDate:     rssPubDate(e),
+			Description: fmt.Sprintf(`<img src="%s">%s`, e.ImageURL, e.AltText),
+		})
+	}
+	doc := rssFeed{Version: "2.0", Channel: channel}
+	b, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rss feed: %v", err)
+	}
+	var buf bytes.Buffer
+	io.WriteString(&buf, xml.Header)
+	buf.Write(b)
+	return buf.Bytes(), nil
+}
+
+func feedLink(feed *Feed) string {
+	return alternateLink(feed.Link)
+}
+
+// rssPubDate formats an entry's timestamp as RFC1123Z, falling back to
+// an empty string if the Atom timestamp can't be parsed.
+func rssPubDate(e canonicalEntry) string {
+	ts := e.Published
+	if ts == "" {
+		ts = e.Updated
+	}
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC1123Z)
+}
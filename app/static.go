@@ -0,0 +1,34 @@
+package app
+
+import (
+	"io"
+	"net/http"
+)
+
+// feedXSL renders an Atom feed as HTML when a browser opens /atom.xml
+// directly instead of treating it as raw XML, via the xml-stylesheet PI
+// atomHandler emits.
+const feedXSL = `<?xml version="1.0" encoding="UTF-8"?>
+<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform" xmlns:atom="http://www.w3.org/2005/Atom">
+<xsl:output method="html" encoding="UTF-8" indent="yes"/>
+<xsl:template match="/atom:feed">
+<html>
+<head><title><xsl:value-of select="atom:title"/></title></head>
+<body>
+<h1><xsl:value-of select="atom:title"/></h1>
+<xsl:for-each select="atom:entry">
+<div class="entry">
+<h2><xsl:value-of select="atom:title"/></h2>
+<xsl:copy-of select="atom:summary"/>
+</div>
+</xsl:for-each>
+</body>
+</html>
+</xsl:template>
+</xsl:stylesheet>
+`
+
+func staticFeedXSLHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/xsl")
+	io.WriteString(w, feedXSL)
+}